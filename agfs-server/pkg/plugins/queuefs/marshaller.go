@@ -0,0 +1,134 @@
+package queuefs
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/proto"
+)
+
+// Marshaller converts between a Go value and the wire bytes carried in a
+// queue message body. SQSBackend takes an optional Marshaller at Initialize
+// time. Enqueue/Dequeue send and receive msg.Data as-is whether or not one
+// is configured; a configured Marshaller is applied only by
+// EnqueueTyped/DequeueTyped, and otherwise only adds a ContentType message
+// attribute on send.
+type Marshaller interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	ContentType() string
+}
+
+// JSONMarshaller marshals with encoding/json.
+type JSONMarshaller struct{}
+
+func (JSONMarshaller) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (JSONMarshaller) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (JSONMarshaller) ContentType() string                { return "application/json" }
+
+// AvroMarshaller encodes/decodes values against a fixed Avro schema.
+type AvroMarshaller struct {
+	schema avro.Schema
+}
+
+// NewAvroMarshaller parses schemaJSON (an Avro schema definition) and
+// returns a Marshaller bound to it.
+func NewAvroMarshaller(schemaJSON string) (*AvroMarshaller, error) {
+	schema, err := avro.Parse(schemaJSON)
+	if err != nil {
+		return nil, fmt.Errorf("parse avro schema failed: %w", err)
+	}
+	return &AvroMarshaller{schema: schema}, nil
+}
+
+func (m *AvroMarshaller) Marshal(v any) ([]byte, error) { return avro.Marshal(m.schema, v) }
+func (m *AvroMarshaller) Unmarshal(data []byte, v any) error {
+	return avro.Unmarshal(m.schema, data, v)
+}
+func (m *AvroMarshaller) ContentType() string { return "application/avro" }
+
+// ProtobufMarshaller encodes/decodes proto.Message values.
+type ProtobufMarshaller struct{}
+
+func (ProtobufMarshaller) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf marshaller requires a proto.Message, got %T", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (ProtobufMarshaller) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf marshaller requires a proto.Message, got %T", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+func (ProtobufMarshaller) ContentType() string { return "application/x-protobuf" }
+
+// EnvelopeUnwrapper expands a single received message into the queuefs
+// messages it actually carries, for providers that wrap payloads in their
+// own envelope before handing them to SQS (an SNS topic fanning out to the
+// queue, or an S3 bucket notification). Implementations that don't
+// recognize the envelope should return msg unchanged.
+type EnvelopeUnwrapper interface {
+	Unwrap(msg QueueMessage) ([]QueueMessage, error)
+}
+
+type snsNotification struct {
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+}
+
+// SNSEnvelopeUnwrapper unwraps the "Message" field of an SNS notification,
+// the envelope shape SQS receives when subscribed to an SNS topic.
+type SNSEnvelopeUnwrapper struct{}
+
+func (SNSEnvelopeUnwrapper) Unwrap(msg QueueMessage) ([]QueueMessage, error) {
+	var note snsNotification
+	if err := json.Unmarshal([]byte(msg.Data), &note); err != nil || note.Type != "Notification" {
+		return []QueueMessage{msg}, nil
+	}
+	unwrapped := msg
+	unwrapped.Data = note.Message
+	return []QueueMessage{unwrapped}, nil
+}
+
+type s3EventNotification struct {
+	Records []struct {
+		S3 struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key string `json:"key"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+}
+
+// S3EnvelopeUnwrapper yields one QueueMessage per Records[i] entry of an S3
+// event notification, surfacing the bucket and key as Attributes.
+type S3EnvelopeUnwrapper struct{}
+
+func (S3EnvelopeUnwrapper) Unwrap(msg QueueMessage) ([]QueueMessage, error) {
+	var evt s3EventNotification
+	if err := json.Unmarshal([]byte(msg.Data), &evt); err != nil || len(evt.Records) == 0 {
+		return []QueueMessage{msg}, nil
+	}
+
+	out := make([]QueueMessage, len(evt.Records))
+	for i, r := range evt.Records {
+		rec := msg
+		rec.ID = fmt.Sprintf("%s-%d", msg.ID, i)
+		rec.Attributes = map[string]string{
+			"bucket": r.S3.Bucket.Name,
+			"key":    r.S3.Object.Key,
+		}
+		out[i] = rec
+	}
+	return out, nil
+}
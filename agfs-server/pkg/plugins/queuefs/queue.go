@@ -0,0 +1,47 @@
+package queuefs
+
+import "time"
+
+// QueueMessage is the unit of data stored in and retrieved from a queue.
+type QueueMessage struct {
+	ID        string
+	Data      string
+	Timestamp time.Time
+
+	// GroupID and DedupID are only meaningful against FIFO queues. GroupID
+	// selects the message's ordering group (MessageGroupId); DedupID, if
+	// set, is used as MessageDeduplicationId instead of relying on the
+	// queue's content-based deduplication. SequenceNumber is populated on
+	// receive from a FIFO queue and is otherwise empty.
+	GroupID        string
+	DedupID        string
+	SequenceNumber string
+
+	// Attributes carries out-of-band metadata surfaced by the backend,
+	// e.g. the bucket/key of an unwrapped S3 event notification.
+	Attributes map[string]string
+}
+
+// QueueBackend is implemented by the storage backends that power queuefs
+// (e.g. sqs, and any future in-memory or redis-backed implementations).
+type QueueBackend interface {
+	Initialize(config map[string]interface{}) error
+	Close() error
+	GetType() string
+
+	Enqueue(queueName string, msg QueueMessage) error
+	Dequeue(queueName string) (QueueMessage, bool, error)
+	Peek(queueName string) (QueueMessage, bool, error)
+
+	EnqueueBatch(queueName string, msgs []QueueMessage) ([]BatchResult, error)
+	DequeueBatch(queueName string, n int) ([]QueueMessage, error)
+
+	Size(queueName string) (int, error)
+	Clear(queueName string) error
+	ListQueues(prefix string) ([]string, error)
+	GetLastEnqueueTime(queueName string) (time.Time, error)
+
+	CreateQueue(queueName string) error
+	RemoveQueue(queueName string) error
+	QueueExists(queueName string) (bool, error)
+}
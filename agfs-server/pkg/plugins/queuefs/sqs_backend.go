@@ -2,6 +2,7 @@ package queuefs
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"sort"
@@ -28,12 +29,37 @@ const (
 	sqsDefaultMaxReceive   = int32(1)
 	sqsDefaultQueueTimeout = 15 * time.Second
 	sqsDeletedQueueTTL     = 2 * time.Minute
+	sqsMaxBatchSize        = 10
 )
 
+// BatchResult is the per-entry outcome of a batch enqueue, keyed by the
+// message ID so callers can retry only the entries that failed.
+type BatchResult struct {
+	ID      string
+	Success bool
+	Err     error
+}
+
+// BatchDeleteError reports the receipt handles SQS failed to delete after a
+// batch receive with autoDelete=true.
+type BatchDeleteError struct {
+	FailedReceiptHandles []string
+}
+
+func (e *BatchDeleteError) Error() string {
+	return fmt.Sprintf("delete received messages partially failed: %d failed", len(e.FailedReceiptHandles))
+}
+
 // SQSClient wraps AWS SQS SDK operations.
 type SQSClient struct {
 	client *sqs.Client
 	region string
+
+	// marshaller and unwrapper are both optional; when nil, SendMessage and
+	// fromSQSMessage preserve the plain-string-body behavior existing users
+	// already depend on.
+	marshaller Marshaller
+	unwrapper  EnvelopeUnwrapper
 }
 
 // NewSQSClient creates a new SQS client from plugin config.
@@ -74,10 +100,13 @@ func NewSQSClient(ctx context.Context, cfg map[string]interface{}) (*SQSClient,
 		})
 	}
 
-	return &SQSClient{
+	client := &SQSClient{
 		client: sqs.NewFromConfig(awsCfg, clientOpts...),
 		region: region,
-	}, nil
+	}
+	client.marshaller, _ = cfg["marshaller"].(Marshaller)
+	client.unwrapper, _ = cfg["envelope_unwrapper"].(EnvelopeUnwrapper)
+	return client, nil
 }
 
 // EnsureQueueURL returns a queue URL, creating the queue if it does not exist.
@@ -109,7 +138,8 @@ func (c *SQSClient) EnsureQueueURL(ctx context.Context, queueName string) (strin
 	return url, nil
 }
 
-// CreateQueue creates a standard SQS queue.
+// CreateQueue creates a standard SQS queue, or a FIFO queue with
+// content-based deduplication when queueName ends in ".fifo".
 func (c *SQSClient) CreateQueue(ctx context.Context, queueName string) error {
 	if ctx == nil {
 		ctx = context.Background()
@@ -118,9 +148,17 @@ func (c *SQSClient) CreateQueue(ctx context.Context, queueName string) error {
 		return fmt.Errorf("queue name cannot be empty")
 	}
 
-	_, err := c.client.CreateQueue(ctx, &sqs.CreateQueueInput{
+	input := &sqs.CreateQueueInput{
 		QueueName: aws.String(queueName),
-	})
+	}
+	if isFifoQueueName(queueName) {
+		input.Attributes = map[string]string{
+			string(types.QueueAttributeNameFifoQueue):                 "true",
+			string(types.QueueAttributeNameContentBasedDeduplication): "true",
+		}
+	}
+
+	_, err := c.client.CreateQueue(ctx, input)
 	if err != nil {
 		// QueueNameExists is okay in idempotent create semantics.
 		var exists *types.QueueNameExists
@@ -262,30 +300,191 @@ func (c *SQSClient) SendMessage(ctx context.Context, queueURL string, msg QueueM
 	}
 
 	ts := msg.Timestamp.UTC().Format(time.RFC3339Nano)
-	_, err := c.client.SendMessage(ctx, &sqs.SendMessageInput{
-		QueueUrl:    aws.String(queueURL),
-		MessageBody: aws.String(msg.Data),
-		MessageAttributes: map[string]types.MessageAttributeValue{
-			"Type": {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(sqsMessageTypeValue),
-			},
-			sqsAttrMessageID: {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(msg.ID),
-			},
-			sqsAttrMessageTS: {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(ts),
-			},
-		},
-	})
-	if err != nil {
+	input := &sqs.SendMessageInput{
+		QueueUrl:          aws.String(queueURL),
+		MessageBody:       aws.String(msg.Data),
+		MessageAttributes: c.messageAttributes(msg.ID, ts),
+	}
+	if isFifoQueueName(queueURL) {
+		if msg.GroupID == "" {
+			return fmt.Errorf("fifo queue requires a MessageGroupId")
+		}
+		input.MessageGroupId = aws.String(msg.GroupID)
+		if msg.DedupID != "" {
+			input.MessageDeduplicationId = aws.String(msg.DedupID)
+		}
+	}
+
+	if _, err := c.client.SendMessage(ctx, input); err != nil {
 		return fmt.Errorf("send message failed: %w", err)
 	}
 	return nil
 }
 
+// messageAttributes builds the standard queuefs message attribute set,
+// adding a ContentType attribute when a Marshaller is configured. The
+// Marshaller itself is never invoked here: QueueMessage.Data is always a
+// string, so SendMessage/fromSQSMessage can't call Marshal/Unmarshal
+// symmetrically on it (Marshal would be fed an already-stringified body, and
+// the receive path has no target type to Unmarshal into). Callers that want
+// Avro/Protobuf/JSON encoding use SQSBackend's EnqueueTyped/DequeueTyped,
+// which do call the configured Marshaller against the caller's actual value.
+func (c *SQSClient) messageAttributes(id, timestamp string) map[string]types.MessageAttributeValue {
+	attrs := map[string]types.MessageAttributeValue{
+		"Type": {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(sqsMessageTypeValue),
+		},
+		sqsAttrMessageID: {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(id),
+		},
+		sqsAttrMessageTS: {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(timestamp),
+		},
+	}
+	if c.marshaller != nil {
+		attrs["ContentType"] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(c.marshaller.ContentType()),
+		}
+	}
+	return attrs
+}
+
+// SendMessageBatch sends msgs in chunks of at most 10 (the SQS
+// SendMessageBatch limit) and returns one BatchResult per input message, in
+// order, so callers can retry only the entries that failed.
+func (c *SQSClient) SendMessageBatch(ctx context.Context, queueURL string, msgs []QueueMessage) ([]BatchResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if queueURL == "" {
+		return nil, fmt.Errorf("queue URL cannot be empty")
+	}
+
+	fifo := isFifoQueueName(queueURL)
+	if fifo {
+		for i, m := range msgs {
+			if m.GroupID == "" {
+				return nil, fmt.Errorf("fifo queue batch requires a MessageGroupId on every message (missing on entry %d)", i)
+			}
+		}
+	}
+
+	results := make([]BatchResult, len(msgs))
+	for start := 0; start < len(msgs); start += sqsMaxBatchSize {
+		end := start + sqsMaxBatchSize
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+		chunk := msgs[start:end]
+
+		entries := make([]types.SendMessageBatchRequestEntry, len(chunk))
+		ids := make([]string, len(chunk))
+		for i, m := range chunk {
+			id := m.ID
+			if id == "" {
+				id = fmt.Sprintf("sqs-%d-%d", time.Now().UnixNano(), start+i)
+			}
+			ids[i] = id
+
+			ts := m.Timestamp
+			if ts.IsZero() {
+				ts = time.Now().UTC()
+			}
+
+			entry := types.SendMessageBatchRequestEntry{
+				Id:                aws.String(fmt.Sprintf("e-%d", i)),
+				MessageBody:       aws.String(m.Data),
+				MessageAttributes: c.messageAttributes(id, ts.UTC().Format(time.RFC3339Nano)),
+			}
+			if fifo {
+				entry.MessageGroupId = aws.String(m.GroupID)
+				if m.DedupID != "" {
+					entry.MessageDeduplicationId = aws.String(m.DedupID)
+				}
+			}
+			entries[i] = entry
+		}
+
+		out, err := c.client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(queueURL),
+			Entries:  entries,
+		})
+		if err != nil {
+			// A chunk-level API error (throttling, network failure, etc.)
+			// only affects this chunk; earlier chunks may already have been
+			// delivered. Record the failure against this chunk's entries
+			// and keep going so the caller still gets a per-entry result
+			// for every message it sent, not just the ones up to the first
+			// failing chunk.
+			chunkErr := fmt.Errorf("send message batch failed: %w", err)
+			for i := range chunk {
+				results[start+i] = BatchResult{ID: ids[i], Success: false, Err: chunkErr}
+			}
+			continue
+		}
+
+		succeeded := make(map[string]struct{}, len(out.Successful))
+		for _, s := range out.Successful {
+			succeeded[aws.ToString(s.Id)] = struct{}{}
+		}
+		failed := make(map[string]types.BatchResultErrorEntry, len(out.Failed))
+		for _, f := range out.Failed {
+			failed[aws.ToString(f.Id)] = f
+		}
+
+		for i := range chunk {
+			entryID := fmt.Sprintf("e-%d", i)
+			idx := start + i
+
+			if _, ok := succeeded[entryID]; ok {
+				results[idx] = BatchResult{ID: ids[i], Success: true}
+				continue
+			}
+			if f, ok := failed[entryID]; ok {
+				results[idx] = BatchResult{
+					ID:      ids[i],
+					Success: false,
+					Err:     fmt.Errorf("%s: %s", aws.ToString(f.Code), aws.ToString(f.Message)),
+				}
+				continue
+			}
+			results[idx] = BatchResult{ID: ids[i], Success: false, Err: fmt.Errorf("no result returned for entry %s", entryID)}
+		}
+	}
+
+	return results, nil
+}
+
+// ReceiveMessagesBatch receives up to n messages, issuing as many
+// ReceiveMessage calls as needed (each capped at SQS's 10-message limit via
+// ReceiveMessages) until n is reached or the queue stops returning messages.
+func (c *SQSClient) ReceiveMessagesBatch(ctx context.Context, queueURL string, n int, autoDelete bool) ([]QueueMessage, error) {
+	if n < 1 {
+		n = 1
+	}
+
+	result := make([]QueueMessage, 0, n)
+	for len(result) < n {
+		want := n - len(result)
+		if want > sqsMaxBatchSize {
+			want = sqsMaxBatchSize
+		}
+		msgs, err := c.ReceiveMessages(ctx, queueURL, int32(want), autoDelete)
+		if err != nil {
+			return result, err
+		}
+		if len(msgs) == 0 {
+			break
+		}
+		result = append(result, msgs...)
+	}
+	return result, nil
+}
+
 // ReceiveMessages receives up to maxMessages from queue URL.
 // If autoDelete=true, it deletes received messages before return (dequeue behavior).
 func (c *SQSClient) ReceiveMessages(ctx context.Context, queueURL string, maxMessages int32, autoDelete bool) ([]QueueMessage, error) {
@@ -315,6 +514,8 @@ func (c *SQSClient) ReceiveMessages(ctx context.Context, queueURL string, maxMes
 		MessageAttributeNames: []string{"All"},
 		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
 			types.MessageSystemAttributeNameSentTimestamp,
+			types.MessageSystemAttributeNameMessageGroupId,
+			types.MessageSystemAttributeNameSequenceNumber,
 		},
 	})
 	if err != nil {
@@ -323,23 +524,26 @@ func (c *SQSClient) ReceiveMessages(ctx context.Context, queueURL string, maxMes
 
 	messages := make([]QueueMessage, 0, len(out.Messages))
 	for _, m := range out.Messages {
-		qm, convErr := fromSQSMessage(m)
+		expanded, convErr := c.fromSQSMessage(m)
 		if convErr != nil {
 			return nil, convErr
 		}
-		messages = append(messages, qm)
+		messages = append(messages, expanded...)
 	}
 
 	if autoDelete && len(out.Messages) > 0 {
 		entries := make([]types.DeleteMessageBatchRequestEntry, 0, len(out.Messages))
+		receiptByID := make(map[string]string, len(out.Messages))
 		for i, m := range out.Messages {
 			if m.ReceiptHandle == nil || *m.ReceiptHandle == "" {
 				continue
 			}
+			id := fmt.Sprintf("d-%d", i)
 			entries = append(entries, types.DeleteMessageBatchRequestEntry{
-				Id:            aws.String(fmt.Sprintf("d-%d", i)),
+				Id:            aws.String(id),
 				ReceiptHandle: m.ReceiptHandle,
 			})
+			receiptByID[id] = *m.ReceiptHandle
 		}
 		if len(entries) > 0 {
 			delOut, delErr := c.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
@@ -350,7 +554,13 @@ func (c *SQSClient) ReceiveMessages(ctx context.Context, queueURL string, maxMes
 				return nil, fmt.Errorf("delete received messages failed: %w", delErr)
 			}
 			if len(delOut.Failed) > 0 {
-				return nil, fmt.Errorf("delete received messages partially failed: %d failed", len(delOut.Failed))
+				failedHandles := make([]string, 0, len(delOut.Failed))
+				for _, f := range delOut.Failed {
+					if rh, ok := receiptByID[aws.ToString(f.Id)]; ok {
+						failedHandles = append(failedHandles, rh)
+					}
+				}
+				return nil, &BatchDeleteError{FailedReceiptHandles: failedHandles}
 			}
 		}
 	}
@@ -358,6 +568,41 @@ func (c *SQSClient) ReceiveMessages(ctx context.Context, queueURL string, maxMes
 	return messages, nil
 }
 
+// DeleteMessage deletes a single message by receipt handle, permanently
+// removing it from the queue.
+func (c *SQSClient) DeleteMessage(ctx context.Context, queueURL, receiptHandle string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if queueURL == "" || receiptHandle == "" {
+		return fmt.Errorf("queue URL and receipt handle cannot be empty")
+	}
+
+	_, err := c.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(queueURL),
+		ReceiptHandle: aws.String(receiptHandle),
+	})
+	return err
+}
+
+// ChangeMessageVisibility updates a message's visibility timeout. A timeout
+// of 0 makes the message immediately visible to other consumers again.
+func (c *SQSClient) ChangeMessageVisibility(ctx context.Context, queueURL, receiptHandle string, visibilityTimeout int32) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if queueURL == "" || receiptHandle == "" {
+		return fmt.Errorf("queue URL and receipt handle cannot be empty")
+	}
+
+	_, err := c.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(queueURL),
+		ReceiptHandle:     aws.String(receiptHandle),
+		VisibilityTimeout: visibilityTimeout,
+	})
+	return err
+}
+
 // QueueExists checks if queue exists.
 func (c *SQSClient) QueueExists(ctx context.Context, queueName string) (bool, error) {
 	if ctx == nil {
@@ -421,6 +666,63 @@ func (b *SQSBackend) GetType() string {
 	return "sqs"
 }
 
+// Marshaller returns the Marshaller configured at Initialize time, or nil
+// if none was set.
+func (b *SQSBackend) Marshaller() Marshaller {
+	if b.sqs == nil {
+		return nil
+	}
+	return b.sqs.marshaller
+}
+
+// EnqueueTyped marshals v with the configured Marshaller and enqueues the
+// result as msg.Data, leaving msg's other fields (ID, Timestamp, GroupID,
+// ...) untouched. The marshalled bytes are base64-encoded before being sent,
+// since SQS requires MessageBody to be valid UTF-8 and Avro/Protobuf output
+// is raw binary.
+func (b *SQSBackend) EnqueueTyped(queueName string, v any, msg QueueMessage) error {
+	if b.sqs == nil {
+		return fmt.Errorf("sqs backend not initialized")
+	}
+	if b.sqs.marshaller == nil {
+		return fmt.Errorf("no marshaller configured")
+	}
+
+	data, err := b.sqs.marshaller.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal message failed: %w", err)
+	}
+	msg.Data = base64.StdEncoding.EncodeToString(data)
+	return b.Enqueue(queueName, msg)
+}
+
+// DequeueTyped removes and returns the first message, unmarshalling its Data
+// into v with the configured Marshaller. v must be a pointer, as required by
+// the Marshaller's Unmarshal. ok is false (with a zero QueueMessage and nil
+// error) when the queue was empty.
+func (b *SQSBackend) DequeueTyped(queueName string, v any) (QueueMessage, bool, error) {
+	if b.sqs == nil {
+		return QueueMessage{}, false, fmt.Errorf("sqs backend not initialized")
+	}
+	if b.sqs.marshaller == nil {
+		return QueueMessage{}, false, fmt.Errorf("no marshaller configured")
+	}
+
+	msg, ok, err := b.Dequeue(queueName)
+	if err != nil || !ok {
+		return msg, ok, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(msg.Data)
+	if err != nil {
+		return msg, true, fmt.Errorf("decode message body failed: %w", err)
+	}
+	if err := b.sqs.marshaller.Unmarshal(data, v); err != nil {
+		return msg, true, fmt.Errorf("unmarshal message failed: %w", err)
+	}
+	return msg, true, nil
+}
+
 func (b *SQSBackend) getQueueURL(ctx context.Context, queueName string, createIfMissing bool) (string, error) {
 	if queueName == "" {
 		return "", fmt.Errorf("queue name cannot be empty")
@@ -505,6 +807,54 @@ func (b *SQSBackend) Dequeue(queueName string) (QueueMessage, bool, error) {
 	return msgs[0], true, nil
 }
 
+// EnqueueBatch sends multiple messages, chunking into SQS's 10-message
+// SendMessageBatch limit, and returns one BatchResult per input message (in
+// the same order) so callers can retry only the entries that failed.
+func (b *SQSBackend) EnqueueBatch(queueName string, msgs []QueueMessage) ([]BatchResult, error) {
+	if b.sqs == nil {
+		return nil, fmt.Errorf("sqs backend not initialized")
+	}
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+	for i := range msgs {
+		if msgs[i].ID == "" {
+			msgs[i].ID = fmt.Sprintf("sqs-%d-%d", time.Now().UnixNano(), i)
+		}
+		if msgs[i].Timestamp.IsZero() {
+			msgs[i].Timestamp = time.Now().UTC()
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sqsDefaultQueueTimeout)
+	defer cancel()
+
+	url, err := b.getQueueURL(ctx, queueName, true)
+	if err != nil {
+		return nil, fmt.Errorf("enqueue batch get queue url failed: %w", err)
+	}
+	return b.sqs.SendMessageBatch(ctx, url, msgs)
+}
+
+// DequeueBatch removes and returns up to n messages.
+func (b *SQSBackend) DequeueBatch(queueName string, n int) ([]QueueMessage, error) {
+	if b.sqs == nil {
+		return nil, fmt.Errorf("sqs backend not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sqsDefaultQueueTimeout)
+	defer cancel()
+
+	url, err := b.getQueueURL(ctx, queueName, false)
+	if err != nil {
+		if isQueueDoesNotExistError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("dequeue batch get queue url failed: %w", err)
+	}
+	return b.sqs.ReceiveMessagesBatch(ctx, url, n, true)
+}
+
 // Peek returns first message without deleting it.
 func (b *SQSBackend) Peek(queueName string) (QueueMessage, bool, error) {
 	if b.sqs == nil {
@@ -707,7 +1057,11 @@ func (b *SQSBackend) QueueExists(queueName string) (bool, error) {
 	return b.sqs.QueueExists(ctx, queueName)
 }
 
-func fromSQSMessage(m types.Message) (QueueMessage, error) {
+// fromSQSMessage decodes a raw SQS message into one or more QueueMessages,
+// running the configured EnvelopeUnwrapper (if any) afterwards so providers
+// that wrap payloads in their own envelope (SNS fan-out, S3 event
+// notifications) can be expanded transparently.
+func (c *SQSClient) fromSQSMessage(m types.Message) ([]QueueMessage, error) {
 	var msg QueueMessage
 
 	if m.Body == nil {
@@ -741,7 +1095,28 @@ func fromSQSMessage(m types.Message) (QueueMessage, error) {
 		msg.Timestamp = time.Now().UTC()
 	}
 
-	return msg, nil
+	// FIFO-only system attributes; empty for standard queues.
+	if gid, ok := m.Attributes[string(types.MessageSystemAttributeNameMessageGroupId)]; ok {
+		msg.GroupID = gid
+	}
+	if seq, ok := m.Attributes[string(types.MessageSystemAttributeNameSequenceNumber)]; ok {
+		msg.SequenceNumber = seq
+	}
+
+	if c.unwrapper == nil {
+		return []QueueMessage{msg}, nil
+	}
+	expanded, err := c.unwrapper.Unwrap(msg)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap envelope failed: %w", err)
+	}
+	return expanded, nil
+}
+
+// isFifoQueueName reports whether name (a queue name or a full queue URL,
+// both of which end in the queue name) designates a FIFO queue.
+func isFifoQueueName(name string) bool {
+	return strings.HasSuffix(name, ".fifo")
 }
 
 func queueNameFromURL(u string) string {
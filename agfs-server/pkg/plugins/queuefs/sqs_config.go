@@ -0,0 +1,219 @@
+package queuefs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// RedrivePolicy configures a dead-letter queue redrive for a queue created
+// or updated via QueueConfig. The dead-letter queue is created (via
+// EnsureQueueURL) if it doesn't already exist.
+type RedrivePolicy struct {
+	DeadLetterQueueName string
+	MaxReceiveCount     int
+}
+
+// QueueConfig describes the tunable attributes of an SQS queue, applied at
+// creation via CreateQueueWithConfig or to an existing queue via
+// SetQueueAttributes. Zero-valued fields are left unset so operators can
+// change a subset of attributes without clobbering the rest.
+type QueueConfig struct {
+	VisibilityTimeout      time.Duration
+	MessageRetentionPeriod time.Duration
+	ReceiveMessageWaitTime time.Duration
+	DelaySeconds           int
+	MaximumMessageSize     int
+	KMSMasterKeyID         string
+	KMSDataKeyReusePeriod  time.Duration
+	RedrivePolicy          *RedrivePolicy
+	Tags                   map[string]string
+}
+
+// CreateQueueWithConfig creates queueName with the attributes described by
+// cfg. If the queue already exists, its attributes are updated in place via
+// SetQueueAttributes instead of erroring, matching CreateQueue's idempotent
+// create semantics.
+func (b *SQSBackend) CreateQueueWithConfig(queueName string, cfg QueueConfig) error {
+	if b.sqs == nil {
+		return fmt.Errorf("sqs backend not initialized")
+	}
+	if strings.Contains(queueName, "/") {
+		return fmt.Errorf("nested queue name %q is not supported by sqs backend", queueName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sqsDefaultQueueTimeout)
+	defer cancel()
+
+	attrs, err := b.buildQueueAttributes(ctx, queueName, cfg, true)
+	if err != nil {
+		return fmt.Errorf("build queue attributes for %q failed: %w", queueName, err)
+	}
+
+	input := &sqs.CreateQueueInput{
+		QueueName:  aws.String(queueName),
+		Attributes: attrs,
+	}
+	if len(cfg.Tags) > 0 {
+		input.Tags = cfg.Tags
+	}
+
+	_, err = b.sqs.client.CreateQueue(ctx, input)
+	if err != nil {
+		var exists *types.QueueNameExists
+		if errors.As(err, &exists) {
+			return b.SetQueueAttributes(queueName, cfg)
+		}
+		return fmt.Errorf("create queue %q failed: %w", queueName, err)
+	}
+
+	b.cacheMu.Lock()
+	delete(b.deleted, queueName)
+	b.cacheMu.Unlock()
+	return nil
+}
+
+// SetQueueAttributes updates an existing queue's attributes from cfg,
+// including tagging it with cfg.Tags if set.
+func (b *SQSBackend) SetQueueAttributes(queueName string, cfg QueueConfig) error {
+	if b.sqs == nil {
+		return fmt.Errorf("sqs backend not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sqsDefaultQueueTimeout)
+	defer cancel()
+
+	url, err := b.getQueueURL(ctx, queueName, false)
+	if err != nil {
+		return fmt.Errorf("set queue attributes get queue url failed: %w", err)
+	}
+
+	attrs, err := b.buildQueueAttributes(ctx, queueName, cfg, false)
+	if err != nil {
+		return fmt.Errorf("build queue attributes for %q failed: %w", queueName, err)
+	}
+
+	if len(attrs) > 0 {
+		_, err = b.sqs.client.SetQueueAttributes(ctx, &sqs.SetQueueAttributesInput{
+			QueueUrl:   aws.String(url),
+			Attributes: attrs,
+		})
+		if err != nil {
+			return fmt.Errorf("set queue attributes for %q failed: %w", queueName, err)
+		}
+	}
+
+	if len(cfg.Tags) > 0 {
+		if err := b.TagQueue(queueName, cfg.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TagQueue applies tags to an existing queue.
+func (b *SQSBackend) TagQueue(queueName string, tags map[string]string) error {
+	if b.sqs == nil {
+		return fmt.Errorf("sqs backend not initialized")
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sqsDefaultQueueTimeout)
+	defer cancel()
+
+	url, err := b.getQueueURL(ctx, queueName, false)
+	if err != nil {
+		return fmt.Errorf("tag queue get queue url failed: %w", err)
+	}
+
+	_, err = b.sqs.client.TagQueue(ctx, &sqs.TagQueueInput{
+		QueueUrl: aws.String(url),
+		Tags:     tags,
+	})
+	if err != nil {
+		return fmt.Errorf("tag queue %q failed: %w", queueName, err)
+	}
+	return nil
+}
+
+// buildQueueAttributes turns cfg into the string-keyed Attributes map SQS
+// expects, resolving RedrivePolicy's dead-letter queue ARN along the way.
+// FifoQueue is create-only/immutable in SQS, so it (and the
+// ContentBasedDeduplication attribute that only matters alongside it) is
+// only included when forCreate is true; SetQueueAttributes against an
+// existing FIFO queue must never send it, or SQS rejects the call with
+// InvalidAttributeName.
+func (b *SQSBackend) buildQueueAttributes(ctx context.Context, queueName string, cfg QueueConfig, forCreate bool) (map[string]string, error) {
+	attrs := map[string]string{}
+
+	if forCreate && isFifoQueueName(queueName) {
+		attrs[string(types.QueueAttributeNameFifoQueue)] = "true"
+		attrs[string(types.QueueAttributeNameContentBasedDeduplication)] = "true"
+	}
+	if cfg.VisibilityTimeout > 0 {
+		attrs[string(types.QueueAttributeNameVisibilityTimeout)] = strconv.Itoa(int(cfg.VisibilityTimeout / time.Second))
+	}
+	if cfg.MessageRetentionPeriod > 0 {
+		attrs[string(types.QueueAttributeNameMessageRetentionPeriod)] = strconv.Itoa(int(cfg.MessageRetentionPeriod / time.Second))
+	}
+	if cfg.ReceiveMessageWaitTime > 0 {
+		attrs[string(types.QueueAttributeNameReceiveMessageWaitTimeSeconds)] = strconv.Itoa(int(cfg.ReceiveMessageWaitTime / time.Second))
+	}
+	if cfg.DelaySeconds > 0 {
+		attrs[string(types.QueueAttributeNameDelaySeconds)] = strconv.Itoa(cfg.DelaySeconds)
+	}
+	if cfg.MaximumMessageSize > 0 {
+		attrs[string(types.QueueAttributeNameMaximumMessageSize)] = strconv.Itoa(cfg.MaximumMessageSize)
+	}
+	if cfg.KMSMasterKeyID != "" {
+		attrs[string(types.QueueAttributeNameKmsMasterKeyId)] = cfg.KMSMasterKeyID
+	}
+	if cfg.KMSDataKeyReusePeriod > 0 {
+		attrs[string(types.QueueAttributeNameKmsDataKeyReusePeriodSeconds)] = strconv.Itoa(int(cfg.KMSDataKeyReusePeriod / time.Second))
+	}
+
+	if cfg.RedrivePolicy != nil {
+		policy, err := b.buildRedrivePolicy(ctx, *cfg.RedrivePolicy)
+		if err != nil {
+			return nil, err
+		}
+		attrs[string(types.QueueAttributeNameRedrivePolicy)] = policy
+	}
+
+	return attrs, nil
+}
+
+func (b *SQSBackend) buildRedrivePolicy(ctx context.Context, policy RedrivePolicy) (string, error) {
+	dlqURL, err := b.sqs.EnsureQueueURL(ctx, policy.DeadLetterQueueName)
+	if err != nil {
+		return "", fmt.Errorf("ensure dead-letter queue %q failed: %w", policy.DeadLetterQueueName, err)
+	}
+
+	out, err := b.sqs.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(dlqURL),
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameQueueArn},
+	})
+	if err != nil {
+		return "", fmt.Errorf("get dead-letter queue arn for %q failed: %w", policy.DeadLetterQueueName, err)
+	}
+	dlqArn := out.Attributes[string(types.QueueAttributeNameQueueArn)]
+
+	raw, err := json.Marshal(map[string]interface{}{
+		"deadLetterTargetArn": dlqArn,
+		"maxReceiveCount":     policy.MaxReceiveCount,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal redrive policy failed: %w", err)
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,227 @@
+package queuefs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	sqsSubscribeWaitSeconds       = int32(20)
+	sqsSubscribeMaxReceive        = int32(10)
+	sqsSubscribeDefaultVisibility = 30 * time.Second
+)
+
+// SubscribeOptions configures a Subscribe consumer loop.
+type SubscribeOptions struct {
+	// VisibilityTimeout is the lease duration handed to SQS for each
+	// received message. Defaults to 30s.
+	VisibilityTimeout time.Duration
+	// KeepAlive, when true, automatically extends the visibility timeout
+	// of un-acked messages at VisibilityTimeout/2 intervals so a slow
+	// consumer doesn't lose its lease mid-processing.
+	KeepAlive bool
+	// ChannelSize sets the buffer of the returned channel. Defaults to 0
+	// (unbuffered).
+	ChannelSize int
+}
+
+// LeasedMessage is a message handed out by Subscribe under an SQS
+// visibility lease. The consumer must resolve the lease by calling exactly
+// one of Ack or Nack; Extend may be called any number of times before that
+// to keep the lease alive.
+type LeasedMessage struct {
+	QueueMessage
+
+	Ack    func(ctx context.Context) error
+	Nack   func(ctx context.Context) error
+	Extend func(ctx context.Context, d time.Duration) error
+}
+
+// Subscribe starts a long-polling consumer goroutine for queueName and
+// streams leased messages on the returned channel until ctx is cancelled,
+// at which point the goroutine stops and closes the channel. Unlike Peek,
+// which sets VisibilityTimeout=0 and makes messages immediately visible to
+// other consumers again, messages streamed here are held under a real SQS
+// visibility lease so a worker pool can process them without racing.
+func (b *SQSBackend) Subscribe(ctx context.Context, queueName string, opts SubscribeOptions) (<-chan LeasedMessage, error) {
+	if b.sqs == nil {
+		return nil, fmt.Errorf("sqs backend not initialized")
+	}
+
+	url, err := b.getQueueURL(ctx, queueName, true)
+	if err != nil {
+		return nil, fmt.Errorf("subscribe get queue url failed: %w", err)
+	}
+
+	visibility := opts.VisibilityTimeout
+	if visibility <= 0 {
+		visibility = sqsSubscribeDefaultVisibility
+	}
+
+	out := make(chan LeasedMessage, opts.ChannelSize)
+	go b.pollMessages(ctx, url, visibility, opts.KeepAlive, out)
+	return out, nil
+}
+
+// pollMessages is queuefs's long-polling receive loop, modeled on the same
+// "loop ReceiveMessage with WaitTimeSeconds, push onto a channel, stop on
+// ctx.Done" shape used by other brokers in this codebase. On shutdown it
+// nacks every lease it has handed out that hasn't since been resolved, so
+// those messages become visible to other consumers immediately instead of
+// sitting invisible for the rest of their visibility timeout.
+func (b *SQSBackend) pollMessages(ctx context.Context, queueURL string, visibility time.Duration, keepAlive bool, out chan<- LeasedMessage) {
+	defer close(out)
+
+	var outstanding []LeasedMessage
+	defer func() {
+		if len(outstanding) == 0 {
+			return
+		}
+		// ctx is already Done here, so a fresh context is needed to actually
+		// issue the ChangeMessageVisibility calls. Nack is a no-op for any
+		// lease a consumer already resolved, so nacking all of them is safe.
+		nackCtx, cancel := context.WithTimeout(context.Background(), sqsDefaultQueueTimeout)
+		defer cancel()
+		for _, leased := range outstanding {
+			if err := leased.Nack(nackCtx); err != nil {
+				log.Warnf("[queuefs] subscribe shutdown nack failed for message %s: %v", leased.ID, err)
+			}
+		}
+	}()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		resp, err := b.sqs.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(queueURL),
+			MaxNumberOfMessages:   sqsSubscribeMaxReceive,
+			VisibilityTimeout:     int32(visibility / time.Second),
+			WaitTimeSeconds:       sqsSubscribeWaitSeconds,
+			MessageAttributeNames: []string{"All"},
+			MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+				types.MessageSystemAttributeNameSentTimestamp,
+				types.MessageSystemAttributeNameMessageGroupId,
+				types.MessageSystemAttributeNameSequenceNumber,
+			},
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warnf("[queuefs] subscribe receive failed for %q: %v", queueURL, err)
+			continue
+		}
+
+		for _, m := range resp.Messages {
+			expanded, convErr := b.sqs.fromSQSMessage(m)
+			if convErr != nil {
+				log.Warnf("[queuefs] subscribe decode failed for %q: %v", queueURL, convErr)
+				continue
+			}
+
+			// An envelope-unwrapped raw message can expand into several
+			// QueueMessages; they all share the same underlying receipt
+			// handle, so they must share one lease: acking/nacking any one
+			// of them resolves the lease for all of them, and a single
+			// keep-alive goroutine extends it on their behalf.
+			leases := b.newLeasedMessages(ctx, expanded, queueURL, aws.ToString(m.ReceiptHandle), visibility, keepAlive)
+			outstanding = append(outstanding, leases...)
+			for _, leased := range leases {
+				select {
+				case out <- leased:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// newLeasedMessages builds the Ack/Nack/Extend closures for a raw SQS
+// message's receipt handle and returns one LeasedMessage per entry in
+// expanded, all sharing that single lease: the closures share one mutex and
+// one resolved flag, so acking or nacking any of the returned messages
+// resolves the lease for the rest, and at most one keep-alive goroutine runs
+// per receipt handle rather than one per expanded message.
+func (b *SQSBackend) newLeasedMessages(ctx context.Context, expanded []QueueMessage, queueURL, receiptHandle string, visibility time.Duration, keepAlive bool) []LeasedMessage {
+	var (
+		mu       sync.Mutex
+		resolved bool
+	)
+
+	extend := func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if resolved {
+			return fmt.Errorf("message %s already resolved", receiptHandle)
+		}
+		return b.sqs.ChangeMessageVisibility(ctx, queueURL, receiptHandle, int32(d/time.Second))
+	}
+	ack := func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if resolved {
+			return nil
+		}
+		resolved = true
+		return b.sqs.DeleteMessage(ctx, queueURL, receiptHandle)
+	}
+	nack := func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if resolved {
+			return nil
+		}
+		resolved = true
+		return b.sqs.ChangeMessageVisibility(ctx, queueURL, receiptHandle, 0)
+	}
+
+	leased := make([]LeasedMessage, len(expanded))
+	for i, qm := range expanded {
+		leased[i] = LeasedMessage{
+			QueueMessage: qm,
+			Ack:          ack,
+			Nack:         nack,
+			Extend:       extend,
+		}
+	}
+
+	if keepAlive && len(leased) > 0 {
+		go keepAliveLoop(ctx, &leased[0], visibility, &mu, &resolved)
+	}
+	return leased
+}
+
+// keepAliveLoop extends msg's visibility timeout every visibility/2 until
+// the lease is resolved (Ack/Nack) or ctx is cancelled.
+func keepAliveLoop(ctx context.Context, msg *LeasedMessage, visibility time.Duration, mu *sync.Mutex, resolved *bool) {
+	ticker := time.NewTicker(visibility / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mu.Lock()
+			done := *resolved
+			mu.Unlock()
+			if done {
+				return
+			}
+			if err := msg.Extend(ctx, visibility); err != nil {
+				log.Warnf("[queuefs] keep-alive extend failed for message %s: %v", msg.ID, err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package queuefs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// SweeperOptions configures StartIdleQueueSweeper.
+type SweeperOptions struct {
+	Interval      time.Duration
+	IdleThreshold time.Duration
+	Prefix        string
+	DryRun        bool
+}
+
+// SweeperStats summarizes the outcome of one sweep tick.
+type SweeperStats struct {
+	Scanned int
+	Deleted int
+	Skipped int
+}
+
+// StartIdleQueueSweeper starts a background goroutine that, on each tick,
+// lists queues matching opts.Prefix and deletes any whose
+// LastModifiedTimestamp is older than opts.IdleThreshold and that hold no
+// visible or in-flight messages. It is intended for dynamic per-client
+// queues (chat sessions, ephemeral request/response channels) that would
+// otherwise leak indefinitely without an external cron job. Deletions go
+// through removeOneQueue so the existing deleted/cache bookkeeping keeps
+// working. The goroutine stops when ctx is cancelled.
+func (b *SQSBackend) StartIdleQueueSweeper(ctx context.Context, opts SweeperOptions) error {
+	if b.sqs == nil {
+		return fmt.Errorf("sqs backend not initialized")
+	}
+	if opts.Interval <= 0 {
+		return fmt.Errorf("sweeper interval must be positive")
+	}
+	if opts.IdleThreshold <= 0 {
+		return fmt.Errorf("sweeper idle threshold must be positive")
+	}
+
+	go b.sweepLoop(ctx, opts)
+	return nil
+}
+
+func (b *SQSBackend) sweepLoop(ctx context.Context, opts SweeperOptions) {
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := b.sweepOnce(ctx, opts)
+			log.Infof("[queuefs] idle queue sweep (prefix=%q dry_run=%t): scanned=%d deleted=%d skipped=%d",
+				opts.Prefix, opts.DryRun, stats.Scanned, stats.Deleted, stats.Skipped)
+		}
+	}
+}
+
+func (b *SQSBackend) sweepOnce(ctx context.Context, opts SweeperOptions) SweeperStats {
+	var stats SweeperStats
+
+	queues, err := b.sqs.ListQueues(ctx, opts.Prefix)
+	if err != nil {
+		log.Warnf("[queuefs] idle queue sweep: list queues failed: %v", err)
+		return stats
+	}
+	stats.Scanned = len(queues)
+
+	cutoff := time.Now().Add(-opts.IdleThreshold)
+	for _, queueName := range queues {
+		idle, err := b.queueIsIdle(ctx, queueName, cutoff)
+		if err != nil {
+			log.Warnf("[queuefs] idle queue sweep: inspect %q failed: %v", queueName, err)
+			stats.Skipped++
+			continue
+		}
+		if !idle {
+			stats.Skipped++
+			continue
+		}
+
+		if opts.DryRun {
+			log.Infof("[queuefs] idle queue sweep: would delete %q (dry run)", queueName)
+			stats.Deleted++
+			continue
+		}
+
+		if err := b.removeOneQueue(ctx, queueName); err != nil {
+			log.Warnf("[queuefs] idle queue sweep: delete %q failed: %v", queueName, err)
+			stats.Skipped++
+			continue
+		}
+		stats.Deleted++
+	}
+	return stats
+}
+
+func (b *SQSBackend) queueIsIdle(ctx context.Context, queueName string, cutoff time.Time) (bool, error) {
+	url, err := b.getQueueURL(ctx, queueName, false)
+	if err != nil {
+		if isQueueDoesNotExistError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	out, err := b.sqs.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl: aws.String(url),
+		AttributeNames: []types.QueueAttributeName{
+			types.QueueAttributeNameLastModifiedTimestamp,
+			types.QueueAttributeNameApproximateNumberOfMessages,
+			types.QueueAttributeNameApproximateNumberOfMessagesNotVisible,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	lastModified, err := strconv.ParseInt(out.Attributes[string(types.QueueAttributeNameLastModifiedTimestamp)], 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid LastModifiedTimestamp: %w", err)
+	}
+	if time.Unix(lastModified, 0).After(cutoff) {
+		return false, nil
+	}
+
+	visible, _ := strconv.Atoi(out.Attributes[string(types.QueueAttributeNameApproximateNumberOfMessages)])
+	inFlight, _ := strconv.Atoi(out.Attributes[string(types.QueueAttributeNameApproximateNumberOfMessagesNotVisible)])
+	return visible == 0 && inFlight == 0, nil
+}